@@ -0,0 +1,55 @@
+package dependency
+
+import "fmt"
+
+// Roots returns the nodes that something else depends on but which have no
+// dependencies of their own: the foundational nodes of the graph.
+func (g *graph[T]) Roots() *Set[T] {
+	roots := NewSet[T]()
+	for node, dependents := range g.dependents {
+		if dependents.Cardinality() > 0 && getOrDefault(g.dependencies, node).Cardinality() == 0 {
+			roots.Add(node)
+		}
+	}
+	return roots
+}
+
+// CompileOrder returns target and its transitive dependencies topologically
+// sorted, dependencies first and target last, the order needed to build
+// target alone rather than the whole graph. It returns an error if target's
+// reachable subgraph contains a cycle.
+func (g *graph[T]) CompileOrder(target T) ([]T, error) {
+	scope := g.TransitiveDependencies(target)
+	scope.Add(target)
+
+	order := make([]T, 0, scope.Cardinality())
+	visited := NewSet[T]()
+	inProgress := NewSet[T]()
+
+	var visit func(node T) error
+	visit = func(node T) error {
+		if visited.Contains(node) {
+			return nil
+		}
+		if inProgress.Contains(node) {
+			return fmt.Errorf("CompileOrder: cycle detected in %v's dependencies at %v", target, node)
+		}
+		inProgress.Add(node)
+		for _, dep := range sortedNodes(g.ImmediateDependencies(node)) {
+			if scope.Contains(dep) {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		inProgress.Remove(node)
+		visited.Add(node)
+		order = append(order, node)
+		return nil
+	}
+
+	if err := visit(target); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
@@ -0,0 +1,30 @@
+package dependency
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestGraph_CompileOrderCycleError builds its fixture directly on the graph
+// struct, bypassing Depend's cycle panic, to exercise CompileOrder's cycle
+// detection within a target's reachable subgraph.
+func TestGraph_CompileOrderCycleError(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := &graph[string]{
+		dependencies: map[string]*Set[string]{
+			"a": NewSet("b"),
+			"b": NewSet("a"),
+		},
+		dependents: map[string]*Set[string]{
+			"a": NewSet("b"),
+			"b": NewSet("a"),
+		},
+		nodeInfo: map[string]*NodeInfo{},
+		alias:    map[string]string{},
+	}
+
+	_, err := g.CompileOrder("a")
+	Expect(err).ToNot(BeNil())
+}
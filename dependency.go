@@ -1,76 +1,223 @@
 package dependency
 
 import (
+	"errors"
 	"fmt"
-
-	"github.com/deckarep/golang-set"
+	"io"
+	"sort"
 )
 
-type Node interface{}
+// ErrConflictingAlias is returned by Alias when alias already resolves to a
+// different canonical node than the one given.
+var ErrConflictingAlias = errors.New("dependency: alias already registered to a different node")
 
-type Graph interface {
-	GraphUpdate
+// Graph is a directed acyclic dependency graph keyed by node type T. Use
+// NewGraph[T]() to construct one, or StringGraph / NewStringGraph() for the
+// common case of string-keyed nodes.
+type Graph[T comparable] interface {
+	GraphUpdate[T]
 	// Returns the set of immediate dependencies of node.
-	ImmediateDependencies(node Node) mapset.Set
+	ImmediateDependencies(node T) *Set[T]
 	// Returns the set of immediate dependents of node.
-	ImmediateDependents(node Node) mapset.Set
+	ImmediateDependents(node T) *Set[T]
 	// Returns the set of all things which node depends on, directly or transitively.
-	TransitiveDependencies(node Node) mapset.Set
+	TransitiveDependencies(node T) *Set[T]
 	// Returns the set of all things which any node in node-set depends on, directly or transitively.
-	TransitiveDependenciesSet(nodeSet mapset.Set) mapset.Set
+	TransitiveDependenciesSet(nodeSet *Set[T]) *Set[T]
 	// Returns the set of all things which depend upon node, directly or transitively.
-	TransitiveDependents(node Node) mapset.Set
+	TransitiveDependents(node T) *Set[T]
 	// Returns the set of all things which depend upon any node in node-set, directly or transitively.
-	TransitiveDependentsSet(nodeSet mapset.Set) mapset.Set
+	TransitiveDependentsSet(nodeSet *Set[T]) *Set[T]
 	// Returns the set of all nodes in graph.
-	Nodes() mapset.Set
+	Nodes() *Set[T]
 	// Returns all nodes in topological order
-	TopologicalSort() []interface{}
+	TopologicalSort() []T
 	// Returns topological comparator based on graph and
-	TopologicalComparator(nodes []interface{}) *comparator
+	TopologicalComparator(nodes []T) *comparator[T]
+	// Attaches or replaces the metadata associated with node.
+	SetNodeInfo(node T, info *NodeInfo)
+	// Returns the metadata associated with node, or nil if none was set.
+	GetNodeInfo(node T) *NodeInfo
+	// Visits every node carrying metadata in sorted order, stopping at the first error returned by fn.
+	ForEach(fn func(T, *NodeInfo) error) error
+	// Writes a Graphviz DOT rendering of the graph to w, labelling each node with its own name and taking
+	// colors and backgrounds from NodeInfo; NodeInfo does not change a node's label.
+	WriteDOT(w io.Writer, opts DOTOptions) error
+	// Returns the graph's strongly connected components via Tarjan's algorithm, each as its own slice.
+	StronglyConnected() [][]T
+	// Returns a new Graph containing only the nodes satisfying pred, with edges induced from this graph.
+	Restrict(pred func(T) bool) Graph[T]
+	// Returns the top-level nodes: those depended upon by something else but with no dependencies of their own.
+	Roots() *Set[T]
+	// Returns target and its transitive dependencies topologically sorted, dependencies first and target last.
+	// Returns an error if target's reachable subgraph contains a cycle.
+	CompileOrder(target T) ([]T, error)
+	// Returns a new Graph with every edge present in either this graph or other.
+	// Returns an error instead if the merge would introduce a cycle.
+	Union(other Graph[T]) (Graph[T], error)
+	// Returns a new Graph keeping only the edges present in both this graph and other.
+	Intersection(other Graph[T]) Graph[T]
+	// Returns a new Graph keeping the edges of this graph that are absent from other.
+	Difference(other Graph[T]) Graph[T]
 }
 
-type GraphUpdate interface {
+// StringGraph is the common case of a Graph keyed by plain strings, kept as
+// a named shim for code written before Graph became generic.
+type StringGraph = Graph[string]
+
+// NewStringGraph returns an empty StringGraph.
+func NewStringGraph() StringGraph {
+	return NewGraph[string]()
+}
+
+// NodeInfo carries presentation metadata for a single node, used when
+// rendering the graph (e.g. via WriteDOT). Value is a free-form payload
+// that callers can use to stash their own data alongside a node.
+type NodeInfo struct {
+	Color      string
+	Background string
+	Value      interface{}
+}
+
+// DOTOptions controls how WriteDOT renders the graph.
+type DOTOptions struct {
+	// Name is the digraph identifier. Defaults to "dependency" if empty.
+	Name string
+}
+
+type GraphUpdate[T comparable] interface {
 	// Adds dependency from node to dep ("node depends on dep"). Forbids circular dependencies.
-	Depend(node Node, dep Node) error
+	Depend(node T, dep T) error
 	// Removes dependency from node to dep removed.
-	RemoveEdge(node Node, dep Node) error
+	RemoveEdge(node T, dep T) error
 	// Removes dependency graph with all references to node removed.
-	RemoveAll(node Node) error
+	RemoveAll(node T) error
 	// Removes the node from the dependency graph without removing it as a dependency of other nodes. That is, removes all outgoing edges from node.
-	RemoveNode(node Node) error
+	RemoveNode(node T) error
+	// Registers alias as another name for node, so future lookups of alias
+	// through the methods taking a single node transparently resolve to
+	// node's canonical identity. TransitiveDependenciesSet,
+	// TransitiveDependentsSet and TopologicalComparator use the nodes they are
+	// given as is and do not resolve aliases. Returns ErrConflictingAlias if
+	// alias already resolves to a different node.
+	Alias(node, alias T) error
 }
 
-type graph struct {
-	dependencies map[Node]mapset.Set
-	dependents   map[Node]mapset.Set
+type graph[T comparable] struct {
+	dependencies map[T]*Set[T]
+	dependents   map[T]*Set[T]
+	nodeInfo     map[T]*NodeInfo
+	alias        map[T]T
 }
 
-func NewGraph() Graph {
-	return &graph{make(map[Node]mapset.Set), make(map[Node]mapset.Set)}
+// NewGraph returns an empty Graph keyed by T.
+func NewGraph[T comparable]() Graph[T] {
+	return &graph[T]{make(map[T]*Set[T]), make(map[T]*Set[T]), make(map[T]*NodeInfo), make(map[T]T)}
 }
-func (g *graph) ImmediateDependencies(node Node) mapset.Set {
-	return getOrDefault(g.dependencies, node)
+
+// resolve returns the canonical node that node refers to, chasing alias
+// indirection until it reaches a name with no further alias registered.
+func (g *graph[T]) resolve(node T) T {
+	for {
+		canonical, ok := g.alias[node]
+		if !ok || canonical == node {
+			return node
+		}
+		node = canonical
+	}
 }
-func (g *graph) ImmediateDependents(node Node) mapset.Set {
-	return getOrDefault(g.dependents, node)
+
+func (g *graph[T]) Alias(node, alias T) error {
+	node = g.resolve(node)
+	if canonical, ok := g.alias[alias]; ok && g.resolve(canonical) != node {
+		return ErrConflictingAlias
+	}
+	g.alias[alias] = node
+	g.migrate(alias, node)
+	return nil
 }
-func (g *graph) TransitiveDependencies(node Node) mapset.Set {
-	return bfs(g.dependencies, mapset.NewSetWith(node))
+
+// migrate folds any dependencies, dependents, and node info already recorded
+// under old onto canonical, so Alias is safe to call after Depend or
+// SetNodeInfo have already referenced old: nothing is left keyed under old's
+// raw name once it's been aliased away.
+func (g *graph[T]) migrate(old, canonical T) {
+	if old == canonical {
+		return
+	}
+	if deps, ok := g.dependencies[old]; ok {
+		for _, dep := range deps.ToSlice() {
+			if dep == canonical {
+				// old -> canonical collapses to a self-reference once old
+				// is folded into canonical; drop the edge rather than
+				// leave canonical depending on itself.
+				if depDependents, ok := g.dependents[dep]; ok {
+					depDependents.Remove(old)
+				}
+				continue
+			}
+			if _, ok := g.dependencies[canonical]; !ok {
+				g.dependencies[canonical] = NewSet[T]()
+			}
+			g.dependencies[canonical].Add(dep)
+			if depDependents, ok := g.dependents[dep]; ok {
+				depDependents.Remove(old)
+				depDependents.Add(canonical)
+			}
+		}
+		delete(g.dependencies, old)
+	}
+	if dependents, ok := g.dependents[old]; ok {
+		for _, dependent := range dependents.ToSlice() {
+			if dependent == canonical {
+				// canonical -> old collapses the same way in the other
+				// direction; drop it instead of creating a cycle.
+				if dependentDeps, ok := g.dependencies[dependent]; ok {
+					dependentDeps.Remove(old)
+				}
+				continue
+			}
+			if _, ok := g.dependents[canonical]; !ok {
+				g.dependents[canonical] = NewSet[T]()
+			}
+			g.dependents[canonical].Add(dependent)
+			if dependentDeps, ok := g.dependencies[dependent]; ok {
+				dependentDeps.Remove(old)
+				dependentDeps.Add(canonical)
+			}
+		}
+		delete(g.dependents, old)
+	}
+	if info, ok := g.nodeInfo[old]; ok {
+		if _, exists := g.nodeInfo[canonical]; !exists {
+			g.nodeInfo[canonical] = info
+		}
+		delete(g.nodeInfo, old)
+	}
+}
+func (g *graph[T]) ImmediateDependencies(node T) *Set[T] {
+	return getOrDefault(g.dependencies, g.resolve(node))
 }
-func (g *graph) TransitiveDependenciesSet(nodeSet mapset.Set) mapset.Set {
+func (g *graph[T]) ImmediateDependents(node T) *Set[T] {
+	return getOrDefault(g.dependents, g.resolve(node))
+}
+func (g *graph[T]) TransitiveDependencies(node T) *Set[T] {
+	return bfs(g.dependencies, NewSet(g.resolve(node)))
+}
+func (g *graph[T]) TransitiveDependenciesSet(nodeSet *Set[T]) *Set[T] {
 	return bfs(g.dependencies, nodeSet)
 }
-func (g *graph) TransitiveDependents(node Node) mapset.Set {
-	return bfs(g.dependents, mapset.NewSetWith(node))
+func (g *graph[T]) TransitiveDependents(node T) *Set[T] {
+	return bfs(g.dependents, NewSet(g.resolve(node)))
 }
-func (g *graph) TransitiveDependentsSet(nodeSet mapset.Set) mapset.Set {
+func (g *graph[T]) TransitiveDependentsSet(nodeSet *Set[T]) *Set[T] {
 	return bfs(g.dependents, nodeSet)
 }
-func (g *graph) Nodes() mapset.Set {
+func (g *graph[T]) Nodes() *Set[T] {
 	return keySet(g.dependencies).Union(keySet(g.dependents))
 }
-func (g *graph) RemoveEdge(node Node, dep Node) error {
+func (g *graph[T]) RemoveEdge(node T, dep T) error {
+	node, dep = g.resolve(node), g.resolve(dep)
 	if _, ok := g.dependencies[node]; ok {
 		delete(g.dependencies, node)
 	} else {
@@ -84,7 +231,8 @@ func (g *graph) RemoveEdge(node Node, dep Node) error {
 	}
 	return nil
 }
-func (g *graph) RemoveAll(node Node) error {
+func (g *graph[T]) RemoveAll(node T) error {
+	node = g.resolve(node)
 	if !g.Nodes().Contains(node) {
 		return fmt.Errorf("Unknown node: %v", node)
 	}
@@ -106,7 +254,8 @@ func (g *graph) RemoveAll(node Node) error {
 	}
 	return nil
 }
-func (g *graph) RemoveNode(node Node) error {
+func (g *graph[T]) RemoveNode(node T) error {
+	node = g.resolve(node)
 	if _, ok := g.dependencies[node]; ok {
 		delete(g.dependencies, node)
 	} else {
@@ -114,70 +263,75 @@ func (g *graph) RemoveNode(node Node) error {
 	}
 	return nil
 }
-func (g *graph) Depend(node Node, dep Node) error {
+func (g *graph[T]) Depend(node T, dep T) error {
+	node, dep = g.resolve(node), g.resolve(dep)
 	if node == dep || g.Depends(dep, node) {
 		panic(fmt.Sprintf("Circular dependency: dependency '%v' already depends from '%v' via: %v", node, dep, g.TransitiveDependencies(dep)))
 	}
 	if _, ok := g.dependencies[node]; !ok {
-		g.dependencies[node] = mapset.NewSet()
+		g.dependencies[node] = NewSet[T]()
 	}
 	g.dependencies[node].Add(dep)
 
 	if _, ok := g.dependents[dep]; !ok {
-		g.dependents[dep] = mapset.NewSet()
+		g.dependents[dep] = NewSet[T]()
 	}
 	g.dependents[dep].Add(node)
 	return nil
 }
-func (g *graph) Depends(x, y Node) bool {
+func (g *graph[T]) Depends(x, y T) bool {
 	return g.TransitiveDependencies(x).Contains(y)
 }
-func keySet(m map[Node]mapset.Set) mapset.Set {
-	keys := mapset.NewSet()
+func keySet[T comparable](m map[T]*Set[T]) *Set[T] {
+	keys := NewSet[T]()
 	for k := range m {
 		keys.Add(k)
 	}
 	return keys
 }
-func bfs(neighbors map[Node]mapset.Set, nodeSet mapset.Set) mapset.Set {
+func nodeInfoKeySet[T comparable](m map[T]*NodeInfo) *Set[T] {
+	keys := NewSet[T]()
+	for k := range m {
+		keys.Add(k)
+	}
+	return keys
+}
+func bfs[T comparable](neighbors map[T]*Set[T], nodeSet *Set[T]) *Set[T] {
 	frontier := nodeSet.ToSlice()
-	visited := mapset.NewSet()
-	next := mapset.NewSet().ToSlice()
+	visited := NewSet[T]()
+	next := make([]T, 0)
 	for 0 < len(frontier) {
-		next = mapset.NewSet().ToSlice()
+		next = make([]T, 0)
 		for _, node := range frontier {
 			visited.Add(node)
-			for _, n := range bfs_frontier(node, neighbors, visited) {
-				next = append(next, n)
-			}
+			next = append(next, bfsFrontier(node, neighbors, visited)...)
 		}
 		frontier = next
 	}
 	return visited.Difference(nodeSet)
 }
-func bfs_frontier(node Node, nodes map[Node]mapset.Set, visited mapset.Set) []interface{} {
-	next := mapset.NewSet().ToSlice()
-	iterator := func(n interface{}) bool { return !visited.Contains(n) }
+func bfsFrontier[T comparable](node T, nodes map[T]*Set[T], visited *Set[T]) []T {
+	next := make([]T, 0)
 	if nodes[node] != nil {
 		for _, n := range nodes[node].ToSlice() {
-			if iterator(n) {
+			if !visited.Contains(n) {
 				next = append(next, n)
 			}
 		}
 	}
 	return next
 }
-func getOrDefault(m map[Node]mapset.Set, node Node) mapset.Set {
+func getOrDefault[T comparable](m map[T]*Set[T], node T) *Set[T] {
 	if value, ok := m[node]; ok {
 		return value
 	} else {
-		return mapset.NewSet()
+		return NewSet[T]()
 	}
 }
 
-func (g *graph) TopologicalSort() []interface{} {
-	sorted := make([]interface{}, 0)
-	inDegree := map[interface{}]int{}
+func (g *graph[T]) TopologicalSort() []T {
+	sorted := make([]T, 0)
+	inDegree := map[T]int{}
 
 	// 1. Calculate inDegree of all vertices by going through every edge of the graph.
 	// Each child gets inDegree++ during breadth-first run.
@@ -190,7 +344,7 @@ func (g *graph) TopologicalSort() []interface{} {
 		}
 	}
 	// 2. Collect all vertices with inDegree == 0 onto a stack.
-	stack := make([]interface{}, 0)
+	stack := make([]T, 0)
 	for rule, value := range inDegree {
 		if value == 0 {
 			stack = append(stack, rule)
@@ -200,7 +354,7 @@ func (g *graph) TopologicalSort() []interface{} {
 
 	// 3. While zero-degree-stack is not empty.
 	for len(stack) > 0 {
-		var node interface{}
+		var node T
 		// 3.1. Pop element from zero-degree-stack and append it to topological order.
 		node = stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
@@ -222,33 +376,106 @@ func (g *graph) TopologicalSort() []interface{} {
 	return sorted
 }
 
-type comparator struct {
-	pos map[interface{}]int
+// comparator implements sort.Interface over a fixed slice of values, ordering
+// them by the topological position recorded in pos.
+type comparator[T comparable] struct {
+	values []T
+	pos    map[T]int
 }
 
-func (s comparator) Len() int {
-	return len(s.pos)
+func (s *comparator[T]) Len() int {
+	return len(s.values)
 }
-func (s comparator) Swap(i, j int) {
-	s.pos[i], s.pos[j] = s.pos[j], s.pos[i]
+func (s *comparator[T]) Swap(i, j int) {
+	s.values[i], s.values[j] = s.values[j], s.values[i]
 }
-func (s comparator) Less(i, j int) bool {
-	return s.pos[i] < s.pos[j]
+func (s *comparator[T]) Less(i, j int) bool {
+	return s.pos[s.values[i]] < s.pos[s.values[j]]
 }
-func (s comparator) Values() []interface{} {
-	keys := make([]interface{}, 0)
-	for k := range s.pos {
-		keys = append(keys, k)
-	}
-	return keys
+func (s *comparator[T]) Values() []T {
+	return s.values
 }
-func (g *graph) TopologicalComparator(nodes []interface{}) *comparator {
-	nodes2 := mapset.NewSetFromSlice(nodes)
-	pos := map[interface{}]int{}
+func (g *graph[T]) TopologicalComparator(nodes []T) *comparator[T] {
+	nodes2 := NewSetFromSlice(nodes)
+	pos := map[T]int{}
+	values := make([]T, 0, len(nodes))
 	for order, element := range g.TopologicalSort() {
 		if nodes2.Contains(element) {
 			pos[element] = order
 		}
 	}
-	return &comparator{pos}
+	for _, node := range nodes {
+		if _, ok := pos[node]; ok {
+			values = append(values, node)
+		}
+	}
+	return &comparator[T]{values, pos}
+}
+
+func (g *graph[T]) SetNodeInfo(node T, info *NodeInfo) {
+	g.nodeInfo[g.resolve(node)] = info
+}
+
+func (g *graph[T]) GetNodeInfo(node T) *NodeInfo {
+	return g.nodeInfo[g.resolve(node)]
+}
+
+func (g *graph[T]) ForEach(fn func(T, *NodeInfo) error) error {
+	for _, node := range sortedNodes(nodeInfoKeySet(g.nodeInfo)) {
+		if err := fn(node, g.nodeInfo[node]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *graph[T]) WriteDOT(w io.Writer, opts DOTOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "dependency"
+	}
+	if _, err := fmt.Fprintf(w, "digraph %s {\n", name); err != nil {
+		return err
+	}
+	for _, node := range sortedNodes(g.Nodes()) {
+		if _, err := fmt.Fprintf(w, "\t%s;\n", dotNodeStatement(node, g.nodeInfo[node])); err != nil {
+			return err
+		}
+	}
+	for _, node := range sortedNodes(g.Nodes()) {
+		for _, dep := range sortedNodes(g.ImmediateDependencies(node)) {
+			if _, err := fmt.Fprintf(w, "\t%q -> %q;\n", fmt.Sprint(node), fmt.Sprint(dep)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprint(w, "}\n")
+	return err
+}
+
+// dotNodeStatement renders a single DOT node statement: the label is the
+// node's own name, and color and background attributes come from info when
+// present.
+func dotNodeStatement[T comparable](node T, info *NodeInfo) string {
+	label := fmt.Sprint(node)
+	attrs := fmt.Sprintf("label=%q", label)
+	if info != nil {
+		if info.Color != "" {
+			attrs += fmt.Sprintf(", color=%q", info.Color)
+		}
+		if info.Background != "" {
+			attrs += fmt.Sprintf(", style=filled, fillcolor=%q", info.Background)
+		}
+	}
+	return fmt.Sprintf("%q [%s]", label, attrs)
+}
+
+// sortedNodes returns the elements of set ordered by their string
+// representation, giving callers (e.g. WriteDOT, ForEach) deterministic output.
+func sortedNodes[T comparable](set *Set[T]) []T {
+	nodes := set.ToSlice()
+	sort.Slice(nodes, func(i, j int) bool {
+		return fmt.Sprint(nodes[i]) < fmt.Sprint(nodes[j])
+	})
+	return nodes
 }
@@ -3,10 +3,10 @@ package dependency_test
 import (
 	"testing"
 
+	"bytes"
 	"sort"
 
 	"github.com/andrzejsliwa/dependency"
-	"github.com/deckarep/golang-set"
 	. "github.com/onsi/gomega"
 )
 
@@ -22,8 +22,8 @@ import (
 //         |
 //         d
 //
-func graph1() dependency.Graph {
-	g := dependency.NewGraph()
+func graph1() dependency.StringGraph {
+	g := dependency.NewGraph[string]()
 	g.Depend("b", "a")
 	g.Depend("c", "b")
 	g.Depend("c", "a")
@@ -45,8 +45,8 @@ func graph1() dependency.Graph {
 //    |  /
 //   seven
 //
-func graph2() dependency.Graph {
-	g := dependency.NewGraph()
+func graph2() dependency.StringGraph {
+	g := dependency.NewGraph[string]()
 	g.Depend("two", "one")
 	g.Depend("three", "two")
 	g.Depend("four", "two")
@@ -76,8 +76,8 @@ func graph2() dependency.Graph {
 //                level4
 //
 // ... and so on in a repeating pattern like that, up to level26
-func graph3() dependency.Graph {
-	g := dependency.NewGraph()
+func graph3() dependency.StringGraph {
+	g := dependency.NewGraph[string]()
 	g.Depend("level1a", "level0")
 	g.Depend("level1b", "level0")
 	g.Depend("level1c", "level0")
@@ -165,12 +165,12 @@ func TestGraph_TransitiveDependencies(t *testing.T) {
 	RegisterTestingT(t)
 
 	var cases = []struct {
-		in       dependency.Graph
+		in       dependency.StringGraph
 		node     string
-		expected mapset.Set
+		expected *dependency.Set[string]
 	}{
-		{graph1(), "d", mapset.NewSet("a", "b", "c")},
-		{graph2(), "seven", mapset.NewSet("four", "three", "two", "five", "one", "six")},
+		{graph1(), "d", dependency.NewSet("a", "b", "c")},
+		{graph2(), "seven", dependency.NewSet("four", "three", "two", "five", "one", "six")},
 	}
 
 	for _, tt := range cases {
@@ -184,14 +184,14 @@ func TestGraph_ImmediateDependencies(t *testing.T) {
 	RegisterTestingT(t)
 
 	expected := graph2().ImmediateDependencies("four")
-	Expect(expected).To(Equal(mapset.NewSet("two", "five")))
+	Expect(expected).To(Equal(dependency.NewSet("two", "five")))
 }
 
 func TestGraph_ImmediateDependents(t *testing.T) {
 	RegisterTestingT(t)
 
 	expected := graph2().ImmediateDependents("four")
-	Expect(expected).To(Equal(mapset.NewSet("seven")))
+	Expect(expected).To(Equal(dependency.NewSet("seven")))
 }
 
 func TestGraph_RemoveEdgeSucceed(t *testing.T) {
@@ -214,7 +214,7 @@ func TestGraph_RemoveNodeSucceed(t *testing.T) {
 	graph := graph2()
 	err := graph.RemoveNode("three")
 	Expect(err).To(BeNil())
-	Expect(graph.Nodes()).To(Equal(mapset.NewSet("three", "five", "one", "seven", "two", "four", "six")))
+	Expect(graph.Nodes()).To(Equal(dependency.NewSet("three", "five", "one", "seven", "two", "four", "six")))
 }
 
 func TestGraph_RemoveNodeFailed(t *testing.T) {
@@ -230,7 +230,7 @@ func TestGraph_RemoveAllSucceed(t *testing.T) {
 	graph := graph2()
 	err := graph.RemoveAll("three")
 	Expect(err).To(BeNil())
-	Expect(graph.Nodes()).To(Equal(mapset.NewSet("five", "one", "seven", "two", "four", "six")))
+	Expect(graph.Nodes()).To(Equal(dependency.NewSet("five", "one", "seven", "two", "four", "six")))
 }
 
 func TestGraph_RemoveAllFailed(t *testing.T) {
@@ -244,7 +244,7 @@ func TestGraph_RemoveAllFailed(t *testing.T) {
 func TestGraph_TransitiveDependenciesDeep(t *testing.T) {
 	RegisterTestingT(t)
 
-	expected := mapset.NewSet("level0", "level1a", "level1b", "level1c", "level1d",
+	expected := dependency.NewSet("level0", "level1a", "level1b", "level1c", "level1d",
 		"level2",
 		"level3a", "level3b", "level3c", "level3d",
 		"level4",
@@ -269,13 +269,13 @@ func TestGraph_TransitiveDependenciesSet(t *testing.T) {
 	RegisterTestingT(t)
 
 	var cases = []struct {
-		in       dependency.Graph
-		nodes    mapset.Set
-		expected mapset.Set
+		in       dependency.StringGraph
+		nodes    *dependency.Set[string]
+		expected *dependency.Set[string]
 	}{
-		{graph2(), mapset.NewSet("six", "four"), mapset.NewSet("one", "two", "three", "five")},
-		{graph2(), mapset.NewSet("two", "four"), mapset.NewSet("one", "five")},
-		{graph2(), mapset.NewSet("three", "six"), mapset.NewSet("two", "one")},
+		{graph2(), dependency.NewSet("six", "four"), dependency.NewSet("one", "two", "three", "five")},
+		{graph2(), dependency.NewSet("two", "four"), dependency.NewSet("one", "five")},
+		{graph2(), dependency.NewSet("three", "six"), dependency.NewSet("two", "one")},
 	}
 
 	for _, tt := range cases {
@@ -289,12 +289,12 @@ func TestGraph_TransitiveDependents(t *testing.T) {
 	RegisterTestingT(t)
 
 	var cases = []struct {
-		in       dependency.Graph
+		in       dependency.StringGraph
 		node     string
-		expected mapset.Set
+		expected *dependency.Set[string]
 	}{
-		{graph2(), "three", mapset.NewSet("six", "seven")},
-		{graph2(), "five", mapset.NewSet("four", "seven")},
+		{graph2(), "three", dependency.NewSet("six", "seven")},
+		{graph2(), "five", dependency.NewSet("four", "seven")},
 	}
 
 	for _, tt := range cases {
@@ -308,12 +308,12 @@ func TestGraph_TransitiveDependentsSet(t *testing.T) {
 	RegisterTestingT(t)
 
 	var cases = []struct {
-		in       dependency.Graph
-		nodes    mapset.Set
-		expected mapset.Set
+		in       dependency.StringGraph
+		nodes    *dependency.Set[string]
+		expected *dependency.Set[string]
 	}{
-		{graph2(), mapset.NewSet("four", "three"), mapset.NewSet("six", "seven")},
-		{graph2(), mapset.NewSet("four", "six"), mapset.NewSet("seven")},
+		{graph2(), dependency.NewSet("four", "three"), dependency.NewSet("six", "seven")},
+		{graph2(), dependency.NewSet("four", "six"), dependency.NewSet("seven")},
 	}
 
 	for _, tt := range cases {
@@ -327,33 +327,215 @@ func TestGraph_Nodes(t *testing.T) {
 	RegisterTestingT(t)
 
 	expected := graph2().Nodes()
-	Expect(expected).To(Equal(mapset.NewSet("one", "two", "three", "four", "six", "seven", "five")))
+	Expect(expected).To(Equal(dependency.NewSet("one", "two", "three", "four", "six", "seven", "five")))
 }
 
 func TestGraph_TopologicalSort(t *testing.T) {
 	RegisterTestingT(t)
 
 	actual := graph2().TopologicalSort()
-	nodes := []string{"seven", "four", "five", "six", "three", "two", "one"}
-	expected := make([]interface{}, len(nodes))
-	for i, s := range nodes {
-		expected[i] = s
-	}
+	expected := []string{"seven", "four", "five", "six", "three", "two", "one"}
 	Expect(actual).To(Equal(expected))
 }
 
 func TestGraph_TopologicalComparator(t *testing.T) {
 	RegisterTestingT(t)
 	n := []string{"two", "five", "three"}
-	c := graph2().TopologicalComparator(toInterfaceSlice(n))
+	c := graph2().TopologicalComparator(n)
 	sort.Sort(c)
-	Expect(c.Values()).To(Equal(toInterfaceSlice([]string{"five", "three", "two"})))
+	Expect(c.Values()).To(Equal([]string{"five", "three", "two"}))
 }
 
-func toInterfaceSlice(input []string) []interface{} {
-	nodes := make([]interface{}, len(input))
-	for i, s := range input {
-		nodes[i] = s
-	}
-	return nodes
+func TestGraph_UnionMergesCompatibleGraphs(t *testing.T) {
+	RegisterTestingT(t)
+
+	base := graph1()
+	plugin := dependency.NewGraph[string]()
+	plugin.Depend("e", "d")
+
+	union, err := base.Union(plugin)
+	Expect(err).To(BeNil())
+	Expect(union.Nodes()).To(Equal(dependency.NewSet("a", "b", "c", "d", "e")))
+	Expect(union.ImmediateDependencies("e")).To(Equal(dependency.NewSet("d")))
+	Expect(union.ImmediateDependencies("c")).To(Equal(dependency.NewSet("a", "b")))
+}
+
+func TestGraph_UnionRejectsCycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	g1 := dependency.NewGraph[string]()
+	g1.Depend("x", "y")
+	g2 := dependency.NewGraph[string]()
+	g2.Depend("y", "x")
+
+	_, err := g1.Union(g2)
+	Expect(err).ToNot(BeNil())
+}
+
+func TestGraph_Intersection(t *testing.T) {
+	RegisterTestingT(t)
+
+	g1 := graph2()
+	g2 := dependency.NewGraph[string]()
+	g2.Depend("three", "two")
+	g2.Depend("six", "three")
+
+	result := g1.Intersection(g2)
+	Expect(result.Nodes()).To(Equal(dependency.NewSet("three", "two", "six")))
+	Expect(result.ImmediateDependencies("three")).To(Equal(dependency.NewSet("two")))
+	Expect(result.ImmediateDependencies("six")).To(Equal(dependency.NewSet("three")))
+}
+
+func TestGraph_Difference(t *testing.T) {
+	RegisterTestingT(t)
+
+	g1 := graph2()
+	g2 := dependency.NewGraph[string]()
+	g2.Depend("three", "two")
+
+	result := g1.Difference(g2)
+	Expect(result.ImmediateDependencies("three")).To(Equal(dependency.NewSet[string]()))
+	Expect(result.ImmediateDependencies("four")).To(Equal(dependency.NewSet("two", "five")))
+}
+
+func TestGraph_Roots(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(graph2().Roots()).To(Equal(dependency.NewSet("one", "five")))
+}
+
+func TestGraph_CompileOrder(t *testing.T) {
+	RegisterTestingT(t)
+
+	order, err := graph2().CompileOrder("four")
+	Expect(err).To(BeNil())
+	Expect(order).To(Equal([]string{"five", "one", "two", "four"}))
+}
+
+func TestGraph_CompileOrderLeaf(t *testing.T) {
+	RegisterTestingT(t)
+
+	order, err := graph2().CompileOrder("five")
+	Expect(err).To(BeNil())
+	Expect(order).To(Equal([]string{"five"}))
+}
+
+func TestGraph_Restrict(t *testing.T) {
+	RegisterTestingT(t)
+
+	keep := dependency.NewSet("two", "three", "six", "seven")
+	restricted := graph2().Restrict(func(node string) bool { return keep.Contains(node) })
+
+	Expect(restricted.Nodes()).To(Equal(dependency.NewSet("two", "three", "six", "seven")))
+	Expect(restricted.ImmediateDependencies("three")).To(Equal(dependency.NewSet("two")))
+	Expect(restricted.ImmediateDependencies("six")).To(Equal(dependency.NewSet("three")))
+	Expect(restricted.ImmediateDependencies("seven")).To(Equal(dependency.NewSet("six")))
+}
+
+func TestGraph_AliasResolvesToCanonicalNode(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	err := g.Alias("gtk3", "gtk+-3.0")
+	Expect(err).To(BeNil())
+
+	g.Depend("gtk3", "a")
+	Expect(g.ImmediateDependencies("gtk+-3.0")).To(Equal(dependency.NewSet("a")))
+}
+
+func TestGraph_AliasConflicting(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	Expect(g.Alias("gtk3", "gtk+-3.0")).To(BeNil())
+
+	err := g.Alias("b", "gtk+-3.0")
+	Expect(err).To(Equal(dependency.ErrConflictingAlias))
+}
+
+// TestGraph_AliasMigratesExistingData covers aliasing a name that already
+// has edges and node info recorded against it, e.g. a provider discovering a
+// package under its raw pkg-config name before it's unified with its
+// virtual package alias.
+func TestGraph_AliasMigratesExistingData(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	g.Depend("x", "gtk+-3.0")
+	g.SetNodeInfo("gtk+-3.0", &dependency.NodeInfo{Value: "gtk 3"})
+
+	err := g.Alias("gtk3", "gtk+-3.0")
+	Expect(err).To(BeNil())
+
+	Expect(g.ImmediateDependents("gtk3")).To(Equal(dependency.NewSet("x")))
+	Expect(g.ImmediateDependencies("x")).To(Equal(dependency.NewSet("gtk3")))
+	Expect(g.GetNodeInfo("gtk3")).To(Equal(&dependency.NodeInfo{Value: "gtk 3"}))
+	Expect(g.Nodes()).To(Equal(dependency.NewSet("a", "b", "c", "d", "x", "gtk3")))
+}
+
+// TestGraph_AliasCollapsesDirectEdge covers aliasing two nodes that already
+// have a direct edge between them: migrating that edge onto the canonical
+// name would otherwise leave it depending on itself.
+func TestGraph_AliasCollapsesDirectEdge(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := dependency.NewGraph[string]()
+	g.Depend("A", "B")
+
+	err := g.Alias("B", "A")
+	Expect(err).To(BeNil())
+
+	Expect(g.ImmediateDependencies("B")).To(Equal(dependency.NewSet[string]()))
+	Expect(g.ImmediateDependents("B")).To(Equal(dependency.NewSet[string]()))
+}
+
+func TestGraph_SetGetNodeInfo(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	Expect(g.GetNodeInfo("a")).To(BeNil())
+
+	info := &dependency.NodeInfo{Color: "red", Value: 42}
+	g.SetNodeInfo("a", info)
+	Expect(g.GetNodeInfo("a")).To(Equal(info))
+}
+
+func TestGraph_ForEachSorted(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	g.SetNodeInfo("d", &dependency.NodeInfo{Color: "blue"})
+	g.SetNodeInfo("b", &dependency.NodeInfo{Color: "green"})
+
+	var visited []string
+	err := g.ForEach(func(node string, info *dependency.NodeInfo) error {
+		visited = append(visited, node)
+		return nil
+	})
+	Expect(err).To(BeNil())
+	Expect(visited).To(Equal([]string{"b", "d"}))
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := graph1()
+	g.SetNodeInfo("b", &dependency.NodeInfo{Color: "red", Background: "yellow"})
+
+	var buf bytes.Buffer
+	err := g.WriteDOT(&buf, dependency.DOTOptions{})
+	Expect(err).To(BeNil())
+
+	expected := `digraph dependency {
+	"a" [label="a"];
+	"b" [label="b", color="red", style=filled, fillcolor="yellow"];
+	"c" [label="c"];
+	"d" [label="d"];
+	"b" -> "a";
+	"c" -> "a";
+	"c" -> "b";
+	"d" -> "c";
+}
+`
+	Expect(buf.String()).To(Equal(expected))
 }
@@ -0,0 +1,58 @@
+package dependency_test
+
+import (
+	"testing"
+
+	"github.com/andrzejsliwa/dependency"
+	. "github.com/onsi/gomega"
+)
+
+// diamond builds, with order, the DepSet diamond d -> {b, c} -> a:
+//
+//	  d
+//	 / \
+//	b   c
+//	 \ /
+//	  a
+func diamond(order dependency.Order) *dependency.DepSet[string] {
+	a := dependency.NewDepSetBuilder[string](order).Direct("a").Build()
+	b := dependency.NewDepSetBuilder[string](order).Direct("b").Transitive(a).Build()
+	c := dependency.NewDepSetBuilder[string](order).Direct("c").Transitive(a).Build()
+	d := dependency.NewDepSetBuilder[string](order).Direct("d").Transitive(b, c).Build()
+	return d
+}
+
+func TestDepSet_Postorder(t *testing.T) {
+	RegisterTestingT(t)
+
+	actual := diamond(dependency.POSTORDER).ToList()
+	Expect(actual).To(Equal([]string{"a", "b", "c", "d"}))
+}
+
+func TestDepSet_Preorder(t *testing.T) {
+	RegisterTestingT(t)
+
+	actual := diamond(dependency.PREORDER).ToList()
+	Expect(actual).To(Equal([]string{"d", "b", "a", "c"}))
+}
+
+// TestDepSet_TopologicalMatchesPostorder pins that TOPOLOGICAL is
+// intentionally equivalent to POSTORDER on this package's DepSet, rather
+// than leaving that equivalence looking like an unfinished implementation.
+func TestDepSet_TopologicalMatchesPostorder(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(diamond(dependency.TOPOLOGICAL).ToList()).To(Equal(diamond(dependency.POSTORDER).ToList()))
+}
+
+func TestDepSet_SharedTransitiveWalkedOnce(t *testing.T) {
+	RegisterTestingT(t)
+
+	shared := dependency.NewDepSetBuilder[string](dependency.POSTORDER).Direct("a").Build()
+	parent := dependency.NewDepSetBuilder[string](dependency.POSTORDER).
+		Direct("d").
+		Transitive(shared, shared).
+		Build()
+
+	Expect(parent.ToList()).To(Equal([]string{"a", "d"}))
+}
@@ -0,0 +1,77 @@
+package dependency
+
+// StronglyConnected returns the graph's strongly connected components using
+// Tarjan's algorithm. Each component is a slice of mutually reachable nodes;
+// a node with no cycle through it forms a singleton component on its own.
+// Nodes and their edges are walked in sorted order so the result is
+// deterministic.
+func (g *graph[T]) StronglyConnected() [][]T {
+	index := 0
+	indices := map[T]int{}
+	lowlink := map[T]int{}
+	onStack := map[T]bool{}
+	var stack []T
+	var components [][]T
+
+	var strongconnect func(v T)
+	strongconnect = func(v T) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range sortedNodes(g.ImmediateDependencies(v)) {
+			if _, ok := indices[w]; !ok {
+				strongconnect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] {
+				if indices[w] < lowlink[v] {
+					lowlink[v] = indices[w]
+				}
+			}
+		}
+
+		if lowlink[v] == indices[v] {
+			var component []T
+			for {
+				n := len(stack) - 1
+				w := stack[n]
+				stack = stack[:n]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, v := range sortedNodes(g.Nodes()) {
+		if _, ok := indices[v]; !ok {
+			strongconnect(v)
+		}
+	}
+	return components
+}
+
+// Restrict returns a new Graph containing only the nodes satisfying pred,
+// with edges induced from this graph (an edge is kept only if both its
+// endpoints satisfy pred).
+func (g *graph[T]) Restrict(pred func(T) bool) Graph[T] {
+	restricted := NewGraph[T]()
+	for _, node := range sortedNodes(g.Nodes()) {
+		if !pred(node) {
+			continue
+		}
+		for _, dep := range sortedNodes(g.ImmediateDependencies(node)) {
+			if pred(dep) {
+				restricted.Depend(node, dep)
+			}
+		}
+	}
+	return restricted
+}
@@ -0,0 +1,32 @@
+package dependency
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestGraph_StronglyConnected builds its fixture directly on the graph
+// struct, bypassing Depend's cycle panic, since a -> b -> c -> a is the case
+// StronglyConnected exists to detect.
+func TestGraph_StronglyConnected(t *testing.T) {
+	RegisterTestingT(t)
+
+	g := &graph[string]{
+		dependencies: map[string]*Set[string]{
+			"a": NewSet("b"),
+			"b": NewSet("c"),
+			"c": NewSet("a", "d"),
+			"d": NewSet("e"),
+		},
+		dependents: map[string]*Set[string]{},
+		nodeInfo:   map[string]*NodeInfo{},
+		alias:      map[string]string{},
+	}
+
+	Expect(g.StronglyConnected()).To(Equal([][]string{
+		{"e"},
+		{"d"},
+		{"c", "b", "a"},
+	}))
+}
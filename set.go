@@ -0,0 +1,79 @@
+package dependency
+
+// Set is a minimal generic replacement for mapset.Set. Graph[T] is keyed by
+// an arbitrary comparable type, so sets of nodes need to be generic too;
+// this carries just the operations the rest of the package relies on.
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+// NewSet returns a Set containing the given items.
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{items: make(map[T]struct{}, len(items))}
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+	return s
+}
+
+// NewSetFromSlice returns a Set containing the elements of items.
+func NewSetFromSlice[T comparable](items []T) *Set[T] {
+	return NewSet(items...)
+}
+
+// Add inserts item into the set, returning false if it was already present.
+func (s *Set[T]) Add(item T) bool {
+	if _, ok := s.items[item]; ok {
+		return false
+	}
+	s.items[item] = struct{}{}
+	return true
+}
+
+// Remove deletes item from the set. Removing an absent item is a no-op.
+func (s *Set[T]) Remove(item T) {
+	delete(s.items, item)
+}
+
+// Contains reports whether item is a member of the set.
+func (s *Set[T]) Contains(item T) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+// Cardinality returns the number of items in the set.
+func (s *Set[T]) Cardinality() int {
+	return len(s.items)
+}
+
+// ToSlice returns the set's items in unspecified order.
+func (s *Set[T]) ToSlice() []T {
+	result := make([]T, 0, len(s.items))
+	for item := range s.items {
+		result = append(result, item)
+	}
+	return result
+}
+
+// Union returns a new Set containing every item in s or other.
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for item := range s.items {
+		result.Add(item)
+	}
+	for item := range other.items {
+		result.Add(item)
+	}
+	return result
+}
+
+// Difference returns a new Set containing the items in s that are absent from other.
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for item := range s.items {
+		if !other.Contains(item) {
+			result.Add(item)
+		}
+	}
+	return result
+}
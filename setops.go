@@ -0,0 +1,57 @@
+package dependency
+
+import "fmt"
+
+// Union returns a new Graph containing every edge present in g or other. If
+// combining the two edge sets would introduce a cycle, it returns an error
+// instead of a partially merged graph.
+func (g *graph[T]) Union(other Graph[T]) (Graph[T], error) {
+	result := NewGraph[T]()
+	for _, source := range []Graph[T]{g, other} {
+		for _, node := range sortedNodes(source.Nodes()) {
+			for _, dep := range sortedNodes(source.ImmediateDependencies(node)) {
+				if err := safeDepend(result, node, dep); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Intersection returns a new Graph keeping only the edges present in both g and other.
+func (g *graph[T]) Intersection(other Graph[T]) Graph[T] {
+	result := NewGraph[T]()
+	for _, node := range sortedNodes(g.Nodes()) {
+		otherDeps := other.ImmediateDependencies(node)
+		for _, dep := range sortedNodes(g.ImmediateDependencies(node)) {
+			if otherDeps.Contains(dep) {
+				result.Depend(node, dep)
+			}
+		}
+	}
+	return result
+}
+
+// Difference returns a new Graph keeping the edges of g that are absent from other.
+func (g *graph[T]) Difference(other Graph[T]) Graph[T] {
+	result := NewGraph[T]()
+	for _, node := range sortedNodes(g.Nodes()) {
+		otherDeps := other.ImmediateDependencies(node)
+		for _, dep := range sortedNodes(g.ImmediateDependencies(node)) {
+			if !otherDeps.Contains(dep) {
+				result.Depend(node, dep)
+			}
+		}
+	}
+	return result
+}
+
+// safeDepend adds node -> dep to g, reporting the cycle it would create as
+// an error instead of letting Depend panic.
+func safeDepend[T comparable](g Graph[T], node, dep T) error {
+	if node == dep || g.TransitiveDependencies(dep).Contains(node) {
+		return fmt.Errorf("Union: cannot merge edge %v -> %v without introducing a cycle", node, dep)
+	}
+	return g.Depend(node, dep)
+}